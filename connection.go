@@ -0,0 +1,89 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"gopkg.in/errgo.v1"
+	"gopkg.in/goracle.v1/oracle"
+)
+
+// conn wraps an *oracle.Connection to implement database/sql/driver.Conn.
+type conn struct {
+	*oracle.Connection
+	params ConnectionParams
+}
+
+func openConnection(P ConnectionParams) (*conn, error) {
+	oraConn, err := oracle.NewConnection(P.Username, P.Password, P.SID, mode2OCI(P.Role))
+	if err != nil {
+		return nil, errgo.Notef(err, "connect to %s", P)
+	}
+	if err = oraConn.SetWireCrypt(string(P.WireCrypt)); err != nil {
+		oraConn.Close()
+		return nil, errgo.Notef(err, "set wire_crypt=%s", P.WireCrypt)
+	}
+	if err = oraConn.Connect(0, false); err != nil {
+		return nil, errgo.Notef(err, "connect to %s", P)
+	}
+	c := &conn{Connection: oraConn, params: P}
+	if P.TZName != "" {
+		cur := oraConn.NewCursor()
+		_, err = cur.Execute("ALTER SESSION SET TIME_ZONE='"+P.TZName+"'", nil)
+		cur.Close()
+		if err != nil {
+			c.Close()
+			return nil, errgo.Notef(err, "set time_zone=%s", P.TZName)
+		}
+	}
+	return c, nil
+}
+
+// foldColumnName applies the connection's column_name_to_lower /
+// column_name_to_upper DSN options to a column name as returned by the
+// underlying cursor's description.
+func (c *conn) foldColumnName(name string) string {
+	switch {
+	case c.params.ColumnNameToLower:
+		return strings.ToLower(name)
+	case c.params.ColumnNameToUpper:
+		return strings.ToUpper(name)
+	default:
+		return name
+	}
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query, cursor: c.NewCursor()}, nil
+}
+
+func (c *conn) Close() error {
+	return c.Connection.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{c}, nil
+}
+
+type tx struct {
+	c *conn
+}
+
+func (t tx) Commit() error   { return t.c.Connection.Commit() }
+func (t tx) Rollback() error { return t.c.Connection.Rollback() }
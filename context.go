@@ -0,0 +1,226 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+
+	"gopkg.in/errgo.v1"
+)
+
+var (
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.StmtExecContext    = (*stmt)(nil)
+	_ driver.StmtQueryContext   = (*stmt)(nil)
+)
+
+// watchCancel runs f in its own goroutine so it can be raced against
+// ctx.Done(); if ctx is cancelled before f returns, it issues OCIBreak
+// against the connection's OCI session followed by OCIReset, so the
+// in-flight OCI call is interrupted instead of running to completion. A ctx
+// that can never be cancelled (nil Done channel) just runs f inline. It
+// returns f's error, or ctx.Err() if the context won the race; if OCIBreak
+// or OCIReset itself fails, that error is returned instead.
+func (c *conn) watchCancel(ctx context.Context, f func() error) error {
+	if ctx.Done() == nil {
+		return f()
+	}
+
+	done := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return <-errc
+	case <-ctx.Done():
+		// however this case is left, f must have unwound first: the
+		// connection must not be handed back to the pool while the
+		// abandoned goroutine is still driving OCI calls on it.
+		defer func() { <-done }()
+		if err := c.Break(); err != nil {
+			return errgo.Notef(err, "break")
+		}
+		if err := c.Reset(); err != nil {
+			return errgo.Notef(err, "reset")
+		}
+		return ctx.Err()
+	}
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	return c.watchCancel(ctx, func() error {
+		cur := c.NewCursor()
+		defer cur.Close()
+		_, err := cur.Execute("SELECT 1 FROM DUAL", nil)
+		return err
+	})
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	err := c.watchCancel(ctx, func() error {
+		var perr error
+		s, perr = c.Prepare(query)
+		return perr
+	})
+	return s, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s, err := c.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, err := c.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.(driver.StmtQueryContext).QueryContext(ctx, args)
+}
+
+// CheckNamedValue allows sql.Named("name", value) arguments through
+// unchanged; the cursor resolves them against the statement's :name binds
+// by name instead of position.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	return nil
+}
+
+// ConnBeginTx honors opts.ReadOnly (SET TRANSACTION READ ONLY) and rejects
+// any isolation level other than the driver default.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if driver.IsolationLevel(opts.Isolation) != driver.IsolationLevel(0) {
+		return nil, errgo.Newf("goracle: unsupported isolation level %d", opts.Isolation)
+	}
+	if opts.ReadOnly {
+		if err := c.watchCancel(ctx, func() error {
+			cur := c.NewCursor()
+			defer cur.Close()
+			_, err := cur.Execute("SET TRANSACTION READ ONLY", nil)
+			return err
+		}); err != nil {
+			return nil, errgo.Notef(err, "set transaction read only")
+		}
+	}
+	return tx{c}, nil
+}
+
+func namedArgs(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var res driver.Result
+	err := s.conn.watchCancel(ctx, func() error {
+		var eerr error
+		res, eerr = s.execNamed(args)
+		return eerr
+	})
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.conn.watchCancel(ctx, func() error {
+		var qerr error
+		rows, qerr = s.queryNamed(args)
+		return qerr
+	})
+	return rows, err
+}
+
+// execNamed and queryNamed resolve sql.Named("spanish", ...) arguments
+// against the statement's :spanish-style named binds, falling back to
+// positional binds for unnamed arguments.
+func (s *stmt) execNamed(args []driver.NamedValue) (driver.Result, error) {
+	if err := s.applyPrefetch(); err != nil {
+		return nil, err
+	}
+	values, cleanup, err := s.streamBindArgs(namedArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.cursor.Execute(s.query, bindParams(args, values))
+	if err != nil {
+		cleanup()
+		return nil, errgo.Notef(err, "exec %q", s.query)
+	}
+	return res, nil
+}
+
+func (s *stmt) queryNamed(args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.applyPrefetch(); err != nil {
+		return nil, err
+	}
+	values, cleanup, err := s.streamBindArgs(namedArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.cursor.Execute(s.query, bindParams(args, values)); err != nil {
+		cleanup()
+		return nil, errgo.Notef(err, "query %q", s.query)
+	}
+	return &rows{conn: s.conn, cursor: s.cursor}, nil
+}
+
+// bindParams builds the cursor's bind parameters. When every argument is
+// unnamed (the common case: positional ":1, :2, :3" binds from the plain
+// Exec/Query path), it returns the same []interface{} positional binding
+// statement.go's non-context Exec/Query already uses, so existing callers
+// are unaffected. Only once sql.Named is actually used does it switch to a
+// map[string]interface{} keyed by bind name (falling back to the stringified
+// ordinal for any unnamed argument mixed in amongst named ones).
+func bindParams(args []driver.NamedValue, values []driver.Value) interface{} {
+	named := false
+	for _, a := range args {
+		if a.Name != "" {
+			named = true
+			break
+		}
+	}
+	if !named {
+		return valuesToParams(values)
+	}
+	params := make(map[string]interface{}, len(args))
+	for i, a := range args {
+		key := a.Name
+		if key == "" {
+			key = strconv.Itoa(a.Ordinal)
+		}
+		params[key] = values[i]
+	}
+	return params
+}
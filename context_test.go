@@ -0,0 +1,107 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"testing"
+)
+
+// TestContextCancel fires an intentionally slow query and asserts that
+// cancelling the context returns within a bounded time, and that the
+// underlying session is reusable afterwards.
+func TestContextCancel(t *testing.T) {
+	conn := getConnection(t)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := conn.QueryContext(ctx, "SELECT COUNT(*) FROM all_objects, all_objects")
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded && err != context.Canceled {
+		t.Fatalf("expected context.DeadlineExceeded/Canceled, got %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Fatalf("cancellation took %s, expected it to return promptly", elapsed)
+	}
+
+	// the session must still be usable after the break/reset.
+	var one int
+	if err := conn.QueryRowContext(context.Background(), "SELECT 1 FROM DUAL").Scan(&one); err != nil {
+		t.Fatalf("session not reusable after cancellation: %v", err)
+	}
+	if one != 1 {
+		t.Errorf("got %d, awaited 1", one)
+	}
+}
+
+// TestContextPositionalArgs exercises a plain positional ":1" bind through
+// ExecContext/QueryRowContext, the same kind of bind the pre-existing
+// TestTable uses via tx.Exec, to guard against the context path silently
+// changing how positional binds are resolved.
+func TestContextPositionalArgs(t *testing.T) {
+	conn, tx := prepareTable(t)
+	defer conn.Close()
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(context.Background(),
+		"INSERT INTO "+tbl+" (F_int, F_text_spanish) VALUES (-6, :1)", "positional",
+	); err != nil {
+		t.Fatalf("positional insert via context: %v", err)
+	}
+
+	var got string
+	if err := tx.QueryRowContext(context.Background(),
+		"SELECT F_text_spanish FROM "+tbl+" WHERE F_int = :1", -6,
+	).Scan(&got); err != nil {
+		t.Fatalf("positional select via context: %v", err)
+	}
+	if got != "positional" {
+		t.Errorf("got %q, awaited %q", got, "positional")
+	}
+}
+
+// TestNamedArgs exercises sql.Named bind resolution against a statement
+// using Oracle's :name placeholder style.
+func TestNamedArgs(t *testing.T) {
+	conn, tx := prepareTable(t)
+	defer conn.Close()
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(context.Background(),
+		"INSERT INTO "+tbl+" (F_int, F_text_spanish) VALUES (-4, :spanish)",
+		sql.Named("spanish", "Habitación doble"),
+	); err != nil {
+		t.Fatalf("named insert: %v", err)
+	}
+
+	var got string
+	if err := tx.QueryRowContext(context.Background(),
+		"SELECT F_text_spanish FROM "+tbl+" WHERE F_int = -4",
+	).Scan(&got); err != nil {
+		t.Fatalf("select named insert result: %v", err)
+	}
+	if got != "Habitación doble" {
+		t.Errorf("got %q, awaited %q", got, "Habitación doble")
+	}
+}
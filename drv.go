@@ -0,0 +1,204 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package goracle is a database/sql driver for Oracle, built on top of
+// gopkg.in/goracle.v1/oracle.
+package goracle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/errgo.v1"
+	"gopkg.in/goracle.v1/oracle"
+)
+
+// LogonMode mirrors OCI's privileged connection modes.
+type LogonMode uint8
+
+const (
+	// NoRole is a plain, unprivileged logon.
+	NoRole LogonMode = iota
+	// SysDBA requests a SYSDBA logon.
+	SysDBA
+	// SysOper requests a SYSOPER logon.
+	SysOper
+	// SysASM requests a SYSASM logon.
+	SysASM
+)
+
+// WireCrypt controls OCI's SQLNET.ENCRYPTION_CLIENT / SQLNET.CRYPTO_CHECKSUM_CLIENT level.
+type WireCrypt string
+
+const (
+	// WireCryptRejected refuses encrypted/checksummed connections.
+	WireCryptRejected = WireCrypt("rejected")
+	// WireCryptAccepted allows but does not request wire encryption.
+	WireCryptAccepted = WireCrypt("accepted")
+	// WireCryptRequested requests wire encryption if the server supports it.
+	WireCryptRequested = WireCrypt("requested")
+	// WireCryptRequired refuses to connect without wire encryption.
+	WireCryptRequired = WireCrypt("required")
+)
+
+// ConnectionParams holds the parsed representation of a goracle DSN.
+//
+// The DSN is a classic "user/password@sid" connect string, optionally
+// followed by a "?key=value&..." query string of driver options, e.g.
+//
+//	user/pass@host:port/service?role=SYSDBA&tzname=Europe/Budapest&column_name_to_lower=true
+type ConnectionParams struct {
+	Username, Password, SID string
+
+	// Role is the privileged connection mode (SYSDBA/SYSOPER/SYSASM), if any.
+	Role LogonMode
+
+	// TZName, when non-empty, is issued as ALTER SESSION SET TIME_ZONE=
+	// right after connecting, and is used to localize time.Time values
+	// scanned out of DATE/TIMESTAMP columns.
+	TZName string
+
+	// ColumnNameToLower and ColumnNameToUpper fold the names returned by
+	// Rows.Columns(), for cleaner struct-tag mapping with sqlx and friends.
+	ColumnNameToLower, ColumnNameToUpper bool
+
+	// WireCrypt sets the client-side wire encryption/checksum requirement.
+	WireCrypt WireCrypt
+
+	// PrefetchRows and PrefetchMemory are pushed to each statement's
+	// OCI_ATTR_PREFETCH_ROWS / OCI_ATTR_PREFETCH_MEMORY attributes.
+	PrefetchRows, PrefetchMemory int
+}
+
+// String returns the connect string part (without the driver options),
+// suitable for logging.
+func (p ConnectionParams) String() string {
+	return p.Username + "/" + p.Password + "@" + p.SID
+}
+
+// ParseDSN parses a goracle DSN into its connect string and driver options.
+func ParseDSN(dsn string) (ConnectionParams, error) {
+	P := ConnectionParams{WireCrypt: WireCryptAccepted}
+
+	connectString, rawQuery := dsn, ""
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		connectString, rawQuery = dsn[:i], dsn[i+1:]
+	}
+
+	if i := strings.IndexByte(connectString, '@'); i < 0 {
+		return P, errgo.Newf("no '@' in connect string %q", connectString)
+	} else {
+		P.SID = connectString[i+1:]
+		userpass := connectString[:i]
+		if j := strings.IndexByte(userpass, '/'); j >= 0 {
+			P.Username, P.Password = userpass[:j], userpass[j+1:]
+		} else {
+			P.Username = userpass
+		}
+	}
+
+	if rawQuery == "" {
+		return P, nil
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return P, errgo.Notef(err, "parse options %q", rawQuery)
+	}
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		val := vals[len(vals)-1]
+		switch key {
+		case "role":
+			switch strings.ToUpper(val) {
+			case "", "NORMAL":
+				P.Role = NoRole
+			case "SYSDBA":
+				P.Role = SysDBA
+			case "SYSOPER":
+				P.Role = SysOper
+			case "SYSASM":
+				P.Role = SysASM
+			default:
+				return P, errgo.Newf("unknown role %q", val)
+			}
+		case "tzname":
+			P.TZName = val
+		case "column_name_to_lower":
+			if P.ColumnNameToLower, err = strconv.ParseBool(val); err != nil {
+				return P, errgo.Notef(err, "column_name_to_lower=%q", val)
+			}
+		case "column_name_to_upper":
+			if P.ColumnNameToUpper, err = strconv.ParseBool(val); err != nil {
+				return P, errgo.Notef(err, "column_name_to_upper=%q", val)
+			}
+		case "wire_crypt":
+			switch WireCrypt(strings.ToLower(val)) {
+			case WireCryptRejected, WireCryptAccepted, WireCryptRequested, WireCryptRequired:
+				P.WireCrypt = WireCrypt(strings.ToLower(val))
+			default:
+				return P, errgo.Newf("unknown wire_crypt %q", val)
+			}
+		case "prefetch_rows":
+			if P.PrefetchRows, err = strconv.Atoi(val); err != nil {
+				return P, errgo.Notef(err, "prefetch_rows=%q", val)
+			}
+		case "prefetch_memory":
+			if P.PrefetchMemory, err = strconv.Atoi(val); err != nil {
+				return P, errgo.Notef(err, "prefetch_memory=%q", val)
+			}
+		default:
+			return P, errgo.Newf("unknown option %q", key)
+		}
+	}
+	if P.ColumnNameToLower && P.ColumnNameToUpper {
+		return P, errgo.New("column_name_to_lower and column_name_to_upper are mutually exclusive")
+	}
+	return P, nil
+}
+
+// drv implements database/sql/driver.Driver.
+type drv struct{}
+
+func init() {
+	sql.Register("goracle", drv{})
+}
+
+// Open parses dsn (see ParseDSN) and opens a new Oracle connection.
+func (d drv) Open(dsn string) (driver.Conn, error) {
+	P, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return openConnection(P)
+}
+
+func mode2OCI(role LogonMode) oracle.ConnectionMode {
+	switch role {
+	case SysDBA:
+		return oracle.SysDba
+	case SysOper:
+		return oracle.SysOper
+	case SysASM:
+		return oracle.SysAsm
+	default:
+		return oracle.NoMode
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"testing"
+)
+
+func TestParseDSN(t *testing.T) {
+	P, err := ParseDSN("scott/tiger@host:1521/orcl?role=SYSDBA&tzname=Europe/Budapest" +
+		"&column_name_to_lower=true&wire_crypt=required&prefetch_rows=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Username != "scott" || P.Password != "tiger" || P.SID != "host:1521/orcl" {
+		t.Errorf("got connect string %q/%q@%q", P.Username, P.Password, P.SID)
+	}
+	if P.Role != SysDBA {
+		t.Errorf("role: got %v, awaited SysDBA", P.Role)
+	}
+	if P.TZName != "Europe/Budapest" {
+		t.Errorf("tzname: got %q", P.TZName)
+	}
+	if !P.ColumnNameToLower {
+		t.Error("column_name_to_lower not parsed")
+	}
+	if P.WireCrypt != WireCryptRequired {
+		t.Errorf("wire_crypt: got %q", P.WireCrypt)
+	}
+	if P.PrefetchRows != 500 {
+		t.Errorf("prefetch_rows: got %d", P.PrefetchRows)
+	}
+}
+
+func TestParseDSNConflictingCase(t *testing.T) {
+	if _, err := ParseDSN("scott/tiger@orcl?column_name_to_lower=true&column_name_to_upper=true"); err == nil {
+		t.Error("expected error for mutually exclusive column_name_to_lower/upper, got nil")
+	}
+}
+
+// TestTableDSNRole opens a connection with role=SYSDBA and verifies the
+// session actually logged on as SYSDBA.
+func TestTableDSNRole(t *testing.T) {
+	conn := getConnectionWithOptions(t, "role=SYSDBA")
+	defer conn.Close()
+
+	var isDBA string
+	if err := conn.QueryRow(
+		"SELECT SYS_CONTEXT('USERENV', 'ISDBA') FROM DUAL",
+	).Scan(&isDBA); err != nil {
+		t.Fatalf("cannot query ISDBA: %v", err)
+	}
+	if isDBA != "TRUE" {
+		t.Errorf("role=SYSDBA: got ISDBA=%s, awaited TRUE", isDBA)
+	}
+}
+
+// TestTableDSNTZName opens a connection with tzname=Europe/Budapest and
+// verifies ALTER SESSION SET TIME_ZONE took effect.
+func TestTableDSNTZName(t *testing.T) {
+	conn := getConnectionWithOptions(t, "tzname=Europe/Budapest")
+	defer conn.Close()
+
+	var tz string
+	if err := conn.QueryRow("SELECT SESSIONTIMEZONE FROM DUAL").Scan(&tz); err != nil {
+		t.Fatalf("cannot query SESSIONTIMEZONE: %v", err)
+	}
+	if tz != "Europe/Budapest" {
+		t.Errorf("tzname=Europe/Budapest: got SESSIONTIMEZONE=%s", tz)
+	}
+}
+
+// TestTableDSNColumnNameToLower opens a connection with
+// column_name_to_lower=true and verifies Rows.Columns() folds names.
+func TestTableDSNColumnNameToLower(t *testing.T) {
+	conn := getConnectionWithOptions(t, "column_name_to_lower=true")
+	defer conn.Close()
+
+	rows, err := conn.Query("SELECT 1 AS FOO FROM DUAL")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatalf("columns: %v", err)
+	}
+	if len(cols) != 1 || cols[0] != "foo" {
+		t.Errorf("column_name_to_lower=true: got columns %v, awaited [foo]", cols)
+	}
+}
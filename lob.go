@@ -0,0 +1,160 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"database/sql/driver"
+	"io"
+
+	"gopkg.in/errgo.v1"
+	"gopkg.in/goracle.v1/oracle"
+)
+
+// This file streams CLOB/BLOB values into and out of the database in
+// chunks, instead of materializing the whole payload in a Go []byte as
+// ExternalLobVar.WriteAt/ReadAll do. Both directions go through
+// oracle.ExternalLobVar's existing piecewise WriteAt/ReadAt plus
+// ChunkSize/Size (the same OCILobWrite2/OCILobRead2-backed primitives used
+// to size each chunk); this file only adds the goracle-side plumbing that
+// drives them from an io.Reader bind argument or an io.Writer Scan
+// destination.
+//
+// database/sql itself has no notion of an io.Writer Scan destination — its
+// convertAssign only special-cases sql.Scanner — so the read side is
+// exposed as LobWriter, a small sql.Scanner adapter: wrap the destination
+// writer (row.Scan(&goracle.LobWriter{Writer: w})) and the driver pumps the
+// LOB into it in chunks instead of returning a single []byte.
+
+// lobChunkReader is implemented by bind arguments that should be streamed
+// into an empty LOB locator rather than copied into a single []byte.
+type lobChunkReader interface {
+	io.Reader
+}
+
+// BinaryLobReader wraps an io.Reader to mark its contents as binary
+// (BLOB) when passed as a bind argument; without this wrapper, an
+// io.Reader bind argument streams into a CLOB/NCLOB locator instead.
+type BinaryLobReader struct {
+	io.Reader
+}
+
+// LobWriter adapts an io.Writer into a sql.Scanner so it can be used
+// directly as a Scan destination: row.Scan(&LobWriter{Writer: w}) pumps the
+// scanned LOB column into w in chunks rather than buffering it into a
+// single []byte via ReadAll.
+type LobWriter struct {
+	io.Writer
+}
+
+// Scan implements sql.Scanner.
+func (w LobWriter) Scan(src interface{}) error {
+	lob, ok := src.(*oracle.ExternalLobVar)
+	if !ok {
+		return errgo.Newf("goracle: LobWriter.Scan: unsupported source type %T", src)
+	}
+	_, err := readFrom(lob, w.Writer)
+	return err
+}
+
+// writeTo pumps src into lob in chunks sized to the LOB's own chunk size
+// (OCI_ATTR_CHUNK_SIZE) via piecewise WriteAt/OCILobWrite2 calls, so callers
+// never hold the full payload in memory.
+func writeTo(lob *oracle.ExternalLobVar, src io.Reader) (int64, error) {
+	chunkSize, err := lob.ChunkSize()
+	if err != nil {
+		return 0, errgo.Notef(err, "get chunk size")
+	}
+	buf := make([]byte, chunkSize)
+	var off, total int64
+	for {
+		n, rerr := io.ReadFull(src, buf)
+		if n > 0 {
+			if _, werr := lob.WriteAt(buf[:n], off); werr != nil {
+				return total, errgo.Notef(werr, "write %d bytes at %d", n, off)
+			}
+			off += int64(n)
+			total += int64(n)
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if rerr != nil {
+			return total, errgo.Notef(rerr, "read source")
+		}
+	}
+}
+
+// readFrom pumps lob into dst in chunks sized to the LOB's own chunk size
+// via piecewise ReadAt/OCILobRead2 calls, so callers never hold the full
+// payload in memory.
+func readFrom(lob *oracle.ExternalLobVar, dst io.Writer) (int64, error) {
+	chunkSize, err := lob.ChunkSize()
+	if err != nil {
+		return 0, errgo.Notef(err, "get chunk size")
+	}
+	size, err := lob.Size()
+	if err != nil {
+		return 0, errgo.Notef(err, "get size")
+	}
+	buf := make([]byte, chunkSize)
+	var off, total int64
+	for off < size {
+		n, rerr := lob.ReadAt(buf, off)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return total, errgo.Notef(werr, "write %d bytes", n)
+			}
+			off += int64(n)
+			total += int64(n)
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return total, errgo.Notef(rerr, "read lob at %d", off)
+		}
+	}
+	return total, nil
+}
+
+// streamBindArg detects an io.Reader bind argument, allocates an empty LOB
+// locator for it (BLOB for a BinaryLobReader, CLOB/NCLOB for a plain
+// io.Reader) and streams the reader's contents in, returning the locator as
+// the actual bind value passed to the cursor. created reports whether arg
+// was actually converted, so the caller can track the new locator for
+// cleanup if the statement never ends up executing.
+func (s *stmt) streamBindArg(i int, arg driver.Value) (value driver.Value, created bool, err error) {
+	var r io.Reader
+	var binary bool
+	switch v := arg.(type) {
+	case BinaryLobReader:
+		r, binary = v.Reader, true
+	case lobChunkReader:
+		r = v
+	default:
+		return arg, false, nil
+	}
+	lob, err := s.cursor.NewEmptyLob(binary)
+	if err != nil {
+		return nil, false, errgo.Notef(err, "allocate empty lob for bind %d", i)
+	}
+	if _, err = writeTo(lob, r); err != nil {
+		lob.Close()
+		return nil, false, errgo.Notef(err, "stream bind %d into lob", i)
+	}
+	return lob, true, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"database/sql/driver"
+	"io"
+	"time"
+
+	"gopkg.in/goracle.v1/oracle"
+)
+
+// rows wraps an *oracle.Cursor to implement database/sql/driver.Rows.
+type rows struct {
+	conn   *conn
+	cursor *oracle.Cursor
+	cols   []string
+}
+
+func (r *rows) Columns() []string {
+	if r.cols == nil {
+		descs := r.cursor.Description()
+		r.cols = make([]string, len(descs))
+		for i, d := range descs {
+			r.cols[i] = r.conn.foldColumnName(d.Name)
+		}
+	}
+	return r.cols
+}
+
+func (r *rows) Close() error {
+	return r.cursor.Close()
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	row, err := r.cursor.FetchOne()
+	if err != nil {
+		if err == io.EOF || err == oracle.ErrNoMoreRows {
+			return io.EOF
+		}
+		return err
+	}
+	for i, v := range row {
+		if t, ok := v.(time.Time); ok && r.conn.params.TZName != "" {
+			loc, lerr := time.LoadLocation(r.conn.params.TZName)
+			if lerr == nil {
+				v = t.In(loc)
+			}
+		}
+		dest[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"database/sql/driver"
+
+	"gopkg.in/errgo.v1"
+	"gopkg.in/goracle.v1/oracle"
+)
+
+// stmt wraps an *oracle.Cursor to implement database/sql/driver.Stmt.
+type stmt struct {
+	conn   *conn
+	query  string
+	cursor *oracle.Cursor
+}
+
+func (s *stmt) Close() error {
+	return s.cursor.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) applyPrefetch() error {
+	if s.conn.params.PrefetchRows > 0 {
+		if err := s.cursor.SetPrefetchRows(s.conn.params.PrefetchRows); err != nil {
+			return errgo.Notef(err, "prefetch_rows=%d", s.conn.params.PrefetchRows)
+		}
+	}
+	if s.conn.params.PrefetchMemory > 0 {
+		if err := s.cursor.SetPrefetchMemory(s.conn.params.PrefetchMemory); err != nil {
+			return errgo.Notef(err, "prefetch_memory=%d", s.conn.params.PrefetchMemory)
+		}
+	}
+	return nil
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.applyPrefetch(); err != nil {
+		return nil, err
+	}
+	args, cleanup, err := s.streamBindArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.cursor.Execute(s.query, valuesToParams(args))
+	if err != nil {
+		cleanup()
+		return nil, errgo.Notef(err, "exec %q", s.query)
+	}
+	return res, nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.applyPrefetch(); err != nil {
+		return nil, err
+	}
+	args, cleanup, err := s.streamBindArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.cursor.Execute(s.query, valuesToParams(args)); err != nil {
+		cleanup()
+		return nil, errgo.Notef(err, "query %q", s.query)
+	}
+	return &rows{conn: s.conn, cursor: s.cursor}, nil
+}
+
+// streamBindArgs replaces any io.Reader bind arguments with LOB locators
+// filled by streamBindArg, leaving ordinary arguments untouched. The
+// returned cleanup func closes every locator it created; callers must
+// invoke it if the statement doesn't go on to execute successfully, so a
+// failure partway through binding (or the subsequent Execute call) doesn't
+// leak temporary LOB segments on the server.
+func (s *stmt) streamBindArgs(args []driver.Value) ([]driver.Value, func(), error) {
+	var opened []*oracle.ExternalLobVar
+	cleanup := func() {
+		for _, lob := range opened {
+			lob.Close()
+		}
+	}
+	for i, arg := range args {
+		v, created, err := s.streamBindArg(i, arg)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if created {
+			opened = append(opened, v.(*oracle.ExternalLobVar))
+		}
+		args[i] = v
+	}
+	return args, cleanup, nil
+}
+
+func valuesToParams(args []driver.Value) []interface{} {
+	params := make([]interface{}, len(args))
+	for i, a := range args {
+		params[i] = a
+	}
+	return params
+}
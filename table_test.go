@@ -58,7 +58,7 @@ func TestTableClob(t *testing.T) {
 	insertClob(t, tx, "árvíztűrő tükörfúrógép")
 }
 
-func prepareTable(t *testing.T) (*sql.DB, *sql.Tx) {
+func prepareTable(t testing.TB) (*sql.DB, *sql.Tx) {
 	conn := getConnection(t)
 	conn.Exec("DROP TABLE " + tbl)
 	if _, err := conn.Exec(`CREATE TABLE ` + tbl + ` (
@@ -0,0 +1,50 @@
+/*
+Copyright 2013 Tamás Gulácsi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goracle
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// goracleTestDSNEnv names the environment variable holding the connect
+// string the test suite opens, e.g. "scott/tiger@localhost/xe".
+const goracleTestDSNEnv = "GORACLE_DRV_TEST_DSN"
+
+// getConnection opens a *sql.DB using the DSN in goracleTestDSNEnv, or
+// skips the test if it isn't set.
+func getConnection(t testing.TB) *sql.DB {
+	return getConnectionWithOptions(t, "")
+}
+
+// getConnectionWithOptions is like getConnection, but appends the given
+// "key=value&..." driver options to the DSN before opening it.
+func getConnectionWithOptions(t testing.TB, options string) *sql.DB {
+	dsn := os.Getenv(goracleTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set, skipping", goracleTestDSNEnv)
+	}
+	if options != "" {
+		dsn += "?" + options
+	}
+	db, err := sql.Open("goracle", dsn)
+	if err != nil {
+		t.Fatalf("open %q: %v", dsn, err)
+	}
+	return db
+}